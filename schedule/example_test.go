@@ -12,9 +12,12 @@ func ExampleSchedule() {
 	// Every hour.
 	hourly := schedule.NewSchedule(ctx, time.Hour, 0)
 
-	// Once a day at noon.
+	// Once a day at noon UTC.
 	daily := schedule.NewSchedule(ctx, 24*time.Hour, 12*time.Hour)
 
+	// Once a day at noon local time.
+	local := schedule.NewDaily(ctx, 12*time.Hour, time.Local)
+
 	// Twice a day at 03:40 (00:00 + 03:40) and 15:40 (12:00 + 03:40).
 	precise := schedule.NewSchedule(ctx, 12*time.Hour, 3*time.Hour+40*time.Minute)
 
@@ -36,6 +39,8 @@ func ExampleSchedule() {
 		// Do your job here
 		case <-precise.C:
 			// Do your job here
+		case <-local.C:
+			// Do your job here
 		case <-ctx.Done():
 			// Graceful shutdown
 			return
@@ -3,66 +3,394 @@ package schedule
 import (
 	"context"
 	"errors"
+	"math/big"
+	"sync"
 	"time"
 )
 
 // A Schedule holds a channel that is triggered every p period
 // like a time.Ticker. Unlike time.Timer, it can have an offset,
 // and it is aligned with the UNIX epoch time to make it predictable.
+// C is closed when the schedule terminates, so ranging over it ends
+// once the context of the schedule is done or Stop is called.
 type Schedule struct {
 	C    <-chan time.Time
 	p, o time.Duration
+	h    *hooks
+	stop context.CancelFunc
+}
+
+// hooks holds the callbacks of a Schedule shared with its goroutine.
+type hooks struct {
+	mu    sync.Mutex
+	drift func(expected, actual time.Time)
+	skip  func(missed int, at time.Time)
 }
 
 // NewSchedule returns a new Schedule containing a channel that will send
 // the current time on the channel after each tick. The period of the
 // ticks is specified by the duration argument. The schedule will adjust
 // the time interval or drop ticks to make up for slow receivers.
-// The duration d must be greater than zero; if not, NewSchedule will
-// panic.
+// The duration p must be greater than zero and the offset o must not be
+// negative; if not, NewSchedule will panic. An offset greater than or
+// equal to p is reduced modulo p.
 //
 // Similarly to cron a function with a period of two minutes
 // will be executed every even minute, not every two minutes
 // after initialisation.
 //
+// The alignment is relative to the UNIX epoch, not to the wall clock.
+// A period that does not divide a second, minute or hour evenly (e.g.
+// 333ms or 7m) keeps a fixed phase relative to the epoch, so its ticks
+// drift against those boundaries: a 333ms schedule ticks at every
+// instant whose UNIX time in nanoseconds is a multiple of 333ms plus o.
+//
 // Cancel ctx to release associated resources.
 func NewSchedule(ctx context.Context, p time.Duration, o time.Duration) *Schedule {
+	return NewScheduleWithPolicy(ctx, p, o, SkipMissed)
+}
+
+// A MissedPolicy decides what happens with the aligned tick preceding
+// the creation of a Schedule.
+type MissedPolicy int
+
+const (
+	// SkipMissed drops the tick preceding the creation of a Schedule,
+	// the first tick happens at the next aligned time.
+	SkipMissed MissedPolicy = iota
+	// RunMissed sends the tick preceding the creation of a Schedule
+	// immediately. The value sent is the aligned time of the missed
+	// tick, not the current time.
+	RunMissed
+)
+
+// NewScheduleWithPolicy returns a new Schedule like NewSchedule, with
+// mp deciding whether the most recent aligned tick before the call is
+// sent immediately. For example, a schedule with a period of ten minutes
+// created at 10:05 first ticks at 10:10 with SkipMissed, and ticks right
+// away with the value 10:00 with RunMissed.
+//
+// Cancel ctx to release associated resources.
+func NewScheduleWithPolicy(ctx context.Context, p time.Duration, o time.Duration, mp MissedPolicy) *Schedule {
 	if p <= 0 {
 		panic(errors.New("non-positive interval for NewSchedule"))
 	}
+	if o < 0 {
+		panic(errors.New("negative offset for NewSchedule"))
+	}
+	o %= p
 
 	ch := make(chan time.Time)
-	s := Schedule{C: ch}
+	s := Schedule{C: ch, p: p, o: o, h: &hooks{}}
 	// Position the first execution
-	firstT := time.Now().Truncate(p).Add(o)
-	if firstT.Before(time.Now()) {
+	now := time.Now()
+	n := now.UnixNano()
+	firstT := time.Unix(0, n-n%int64(p)+int64(o))
+	if mp == RunMissed && firstT.After(now) {
+		firstT = firstT.Add(-p)
+	} else if mp != RunMissed && firstT.Before(now) {
 		firstT = firstT.Add(p)
 	}
-	first := time.NewTimer(firstT.Sub(time.Now()))
-	// Receiving from a nil channel blocks forever
-	t := &time.Ticker{C: nil}
+	s.start(ctx, ch, now, firstT, mp == RunMissed, every(p), time.Now)
+	return &s
+}
+
+// ErrMisaligned is returned by NewScheduleChecked when the ticks of a
+// schedule do not land on a clean local boundary.
+var ErrMisaligned = errors.New("schedule: ticks are not aligned with the local time of day, use NewDaily")
+
+// NewScheduleChecked returns a new Schedule like NewSchedule, but for a
+// period of a day or longer it first checks that the ticks land on a
+// clean local boundary in loc. Epoch alignment is relative to UTC and
+// to a Thursday, so NewSchedule(ctx, 24*time.Hour, 0) ticks at 05:30 in
+// a UTC+5:30 zone, moves by an hour with daylight saving time, and a
+// weekly schedule ticks on Thursdays.
+//
+// A tick is accepted at o past local midnight, as if o was given in
+// local time, or exactly at local midnight, as when o compensates the
+// UTC offset. For a period of whole weeks the tick must also land on a
+// Monday. The first tick and one about half a year later must land on
+// the same local time and weekday. A period which is not a whole number
+// of days never qualifies. If the check fails, no schedule is
+// started and ErrMisaligned is returned; NewDaily is usually what the
+// caller wants instead.
+// The duration p must be greater than zero, the offset o must not be
+// negative and loc must not be nil; if not, NewScheduleChecked will
+// panic before any check.
+//
+// Cancel ctx to release associated resources.
+func NewScheduleChecked(ctx context.Context, p time.Duration, o time.Duration, loc *time.Location) (*Schedule, error) {
+	if p <= 0 {
+		panic(errors.New("non-positive interval for NewScheduleChecked"))
+	}
+	if o < 0 {
+		panic(errors.New("negative offset for NewScheduleChecked"))
+	}
+	if loc == nil {
+		panic(errors.New("nil location for NewScheduleChecked"))
+	}
+	if p >= 24*time.Hour {
+		if p%(24*time.Hour) != 0 {
+			return nil, ErrMisaligned
+		}
+		n := time.Now().UnixNano()
+		first := time.Unix(0, n-n%int64(p)+int64(o%p)+int64(p))
+		later := first.Add((182*24*time.Hour + p - 1) / p * p)
+		tod, wd := localClock(first, loc)
+		if tod != 0 && tod != o%(24*time.Hour) {
+			return nil, ErrMisaligned
+		}
+		if p%(7*24*time.Hour) == 0 && wd != time.Monday {
+			return nil, ErrMisaligned
+		}
+		if laterTod, laterWd := localClock(later, loc); laterTod != tod || laterWd != wd {
+			return nil, ErrMisaligned
+		}
+	}
+	return NewSchedule(ctx, p, o), nil
+}
+
+// localClock returns the time elapsed at t since the last midnight in
+// loc, and the weekday of t in loc.
+func localClock(t time.Time, loc *time.Location) (time.Duration, time.Weekday) {
+	t = t.In(loc)
+	h, m, sec := t.Clock()
+	tod := time.Duration(h)*time.Hour + time.Duration(m)*time.Minute + time.Duration(sec)*time.Second + time.Duration(t.Nanosecond())
+	return tod, t.Weekday()
+}
+
+// NewScheduleAnchored returns a new Schedule like NewSchedule, but the
+// ticks are aligned with anchor instead of the UNIX epoch. The channel
+// fires at anchor + k*p for every k where that instant is strictly
+// after the time of the call, which makes it suitable for running a job
+// every p since its last successful run.
+// The duration p must be greater than zero; if not, NewScheduleAnchored
+// will panic.
+//
+// Cancel ctx to release associated resources.
+func NewScheduleAnchored(ctx context.Context, p time.Duration, anchor time.Time) *Schedule {
+	if p <= 0 {
+		panic(errors.New("non-positive interval for NewScheduleAnchored"))
+	}
+
+	ch := make(chan time.Time)
+	now := time.Now()
+	o := unixMod(anchor, p)
+	// Time.Sub saturates, so step from now by the phase difference instead
+	r := (unixMod(now, p) - o + p) % p
+	s := Schedule{C: ch, p: p, o: o, h: &hooks{}}
+	s.start(ctx, ch, now, now.Add(p-r), false, every(p), time.Now)
+	return &s
+}
+
+// unixMod returns the time elapsed since the UNIX epoch at t modulo p.
+// Unlike UnixNano it is defined for any t, including the zero Time.
+func unixMod(t time.Time, p time.Duration) time.Duration {
+	n := new(big.Int).Mul(big.NewInt(t.Unix()), big.NewInt(int64(time.Second)))
+	n.Add(n, big.NewInt(int64(t.Nanosecond())))
+	return time.Duration(n.Mod(n, big.NewInt(int64(p))).Int64())
+}
+
+// NewDaily returns a new Schedule containing a channel that will send
+// the current time once a day, when the wall clock in loc shows at past
+// midnight. Unlike NewSchedule with a period of 24 hours, it follows the
+// local calendar, so "noon" stays noon across UTC offsets and daylight
+// saving time changes. On days when at does not exist in loc, e.g. 02:30
+// when clocks spring forward from 02:00 to 03:00, the tick happens at the
+// end of the gap, 03:00. On days when at happens twice, the tick happens
+// at the first occurrence only.
+// The duration at must be in the range [0, 24h) and loc must not be nil;
+// if not, NewDaily will panic.
+//
+// Cancel ctx to release associated resources.
+func NewDaily(ctx context.Context, at time.Duration, loc *time.Location) *Schedule {
+	if at < 0 || at >= 24*time.Hour {
+		panic(errors.New("out of range time of day for NewDaily"))
+	}
+	if loc == nil {
+		panic(errors.New("nil location for NewDaily"))
+	}
+
+	ch := make(chan time.Time)
+	s := Schedule{C: ch, p: 24 * time.Hour, o: at, h: &hooks{}}
+	next := func(prev, now time.Time) (time.Time, int) {
+		missed := 0
+		for t := nextDaily(prev, at, loc); ; t = nextDaily(t, at, loc) {
+			if t.After(now) {
+				return t, missed
+			}
+			missed++
+		}
+	}
+	now := time.Now()
+	s.start(ctx, ch, now, nextDaily(now, at, loc), false, next, time.Now)
+	return &s
+}
+
+// nextDaily returns the first instant strictly after t when the wall
+// clock in loc shows at past midnight.
+func nextDaily(t time.Time, at time.Duration, loc *time.Location) time.Time {
+	t = t.In(loc)
+	y, m, d := t.Date()
+	for {
+		next := wallTime(y, m, d, at, loc)
+		if next.After(t) {
+			return next
+		}
+		d++
+	}
+}
+
+// wallTime returns the instant when the wall clock in loc shows at past
+// midnight on the given day. A wall time skipped by a transition
+// resolves to the end of the gap, a repeated one to its first
+// occurrence. time.Date does not guarantee either.
+func wallTime(y int, m time.Month, d int, at time.Duration, loc *time.Location) time.Time {
+	w := time.Date(y, m, d, 0, 0, int(at/time.Second), int(at%time.Second), time.UTC)
+	// Transitions are further apart than a day, so these are the offsets
+	// on both sides of one happening around w
+	_, before := w.Add(-24 * time.Hour).In(loc).Zone()
+	_, after := w.Add(24 * time.Hour).In(loc).Zone()
+	lo := w.Add(-time.Duration(before) * time.Second).In(loc)
+	hi := w.Add(-time.Duration(after) * time.Second).In(loc)
+	if hi.Before(lo) {
+		lo, hi = hi, lo
+	}
+	for _, t := range []time.Time{lo, hi} {
+		if time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC).Equal(w) {
+			return t
+		}
+	}
+	// Neither offset shows w, search for the transition between them
+	_, offHi := hi.Zone()
+	for hi.Sub(lo) > 1 {
+		mid := lo.Add(hi.Sub(lo) / 2)
+		if _, off := mid.Zone(); off == offHi {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+	return hi
+}
+
+// every returns a function stepping p from prev, and jumping over every
+// step which is not after now at once.
+func every(p time.Duration) func(prev, now time.Time) (time.Time, int) {
+	return func(prev, now time.Time) (time.Time, int) {
+		next := prev.Add(p)
+		if next.After(now) {
+			return next, 0
+		}
+		k := now.Sub(prev)/p + 1
+		return prev.Add(k * p), int(k - 1)
+	}
+}
+
+// NewTicker returns a new Schedule that is not aligned with the UNIX
+// epoch: it ticks every p starting from the time of the call, like a
+// time.Ticker, but it is released by ctx and closes C when done.
+// The duration p must be greater than zero; if not, NewTicker will panic.
+//
+// Cancel ctx to release associated resources.
+func NewTicker(ctx context.Context, p time.Duration) *Schedule {
+	if p <= 0 {
+		panic(errors.New("non-positive interval for NewTicker"))
+	}
+	return NewScheduleAnchored(ctx, p, time.Now())
+}
+
+// start sends on ch at firstT and at every instant returned by next
+// after that until ctx is done, then closes ch. next receives the
+// previous instant and the current time, and returns the first instant
+// following both with the number of instants skipped in between. Those
+// passed while ch was blocked by a slow receiver and they are dropped. If missed is set,
+// firstT is a tick which already passed, it is sent immediately as is,
+// instead of the current time. Otherwise firstT must not be more than
+// the period of s before created, the clock reading it was computed
+// from; if it is, start will panic.
+func (s *Schedule) start(ctx context.Context, ch chan<- time.Time, created, firstT time.Time, missed bool, next func(prev, now time.Time) (time.Time, int), now func() time.Time) {
+	if !missed && created.Sub(firstT) > s.p {
+		panic(errors.New("first tick is more than a period in the past"))
+	}
+	ctx, s.stop = context.WithCancel(ctx)
+	t := time.NewTimer(firstT.Sub(now()))
 	go func() {
+		defer close(ch)
+		defer t.Stop()
+		target := firstT
 		for {
 			select {
-			case v := <-first.C:
-				// The ticker has to be started before f
-				t = time.NewTicker(p)
-				ch <- v
-			case v := <-t.C:
-				ch <- v
-			case <-ctx.Done():
-				if t.C == nil {
-					if !first.Stop() {
-						<-first.C
+			case <-t.C:
+				actual := now()
+				s.h.mu.Lock()
+				drift := s.h.drift
+				s.h.mu.Unlock()
+				// The missed tick is late on purpose, it is not a drift
+				if drift != nil && !missed {
+					drift(target, actual)
+				}
+				v := actual
+				if missed {
+					v, missed = target, false
+				}
+				// A receiver may never come back, do not block after Stop
+				select {
+				case ch <- v:
+				case <-ctx.Done():
+					return
+				}
+				n := now()
+				var skipped int
+				target, skipped = next(target, n)
+				if skipped > 0 {
+					s.h.mu.Lock()
+					skip := s.h.skip
+					s.h.mu.Unlock()
+					if skip != nil {
+						skip(skipped, n)
 					}
-				} else {
-					t.Stop()
 				}
+				t.Reset(target.Sub(n))
+			case <-ctx.Done():
 				return
 			}
 		}
 	}()
-	return &s
+}
+
+// Stop turns off s and releases its resources, like cancelling the
+// context it was created with. C is closed shortly after, a tick which
+// is waiting for a receiver is dropped. Stop does not wait for the
+// goroutine of s to exit and it is safe to call it more than once.
+func (s *Schedule) Stop() {
+	s.stop()
+}
+
+// OnDrift registers f to be called on every tick of s with the aligned
+// time the tick was due and the time its timer fired, so operators can
+// alert when the difference exceeds a threshold. Only the latency of the
+// timer is measured, a growing drift points to an overloaded runtime or
+// clock issues. Time spent waiting for a slow receiver of C is not part
+// of it; ticks dropped because of that are reported by OnSkip. f is
+// called from the goroutine of s before sending on C, it must not block.
+// A nil f removes the callback. The immediate tick of RunMissed is not
+// reported.
+func (s *Schedule) OnDrift(f func(expected, actual time.Time)) {
+	s.h.mu.Lock()
+	s.h.drift = f
+	s.h.mu.Unlock()
+}
+
+// OnSkip registers f to be called whenever ticks of s are dropped
+// because the previous tick was waiting for a receiver past them, with
+// the number of dropped ticks and the time they were noticed. This
+// quantifies how far behind the receiver of C is. f is called from the
+// goroutine of s, it must not block. A nil f removes the callback.
+func (s *Schedule) OnSkip(f func(missed int, at time.Time)) {
+	s.h.mu.Lock()
+	s.h.skip = f
+	s.h.mu.Unlock()
 }
 
 // Period returns the period of s.
@@ -70,7 +398,7 @@ func (s Schedule) Period() time.Duration {
 	return s.p
 }
 
-// Offset returns the offset of s.
+// Offset returns the offset of s, reduced modulo its period.
 func (s Schedule) Offset() time.Duration {
 	return s.o
 }
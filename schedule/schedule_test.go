@@ -2,9 +2,12 @@ package schedule
 
 import (
 	"context"
+	"runtime"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
+	_ "time/tzdata"
 )
 
 const inaccuracy = 17 * time.Millisecond
@@ -84,3 +87,714 @@ func TestSchedule(t *testing.T) {
 		})
 	}
 }
+
+func TestScheduleAnchored(t *testing.T) {
+	tests := []struct {
+		name   string
+		p      time.Duration
+		anchor time.Duration
+		want   time.Duration
+	}{
+		{
+			name:   `anchor in the past`,
+			p:      time.Second,
+			anchor: -2500 * time.Millisecond,
+			want:   500 * time.Millisecond,
+		},
+		{
+			name:   `anchor on a tick`,
+			p:      time.Second / 2,
+			anchor: -time.Second,
+			want:   time.Second / 2,
+		},
+		{
+			name:   `anchor in the future`,
+			p:      time.Second,
+			anchor: 1300 * time.Millisecond,
+			want:   300 * time.Millisecond,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			now := time.Now()
+			anchor := now.Add(tt.anchor)
+			s := NewScheduleAnchored(ctx, tt.p, anchor)
+
+			first := <-s.C
+			if expected := now.Add(tt.want - inaccuracy); first.Before(expected) {
+				t.Fatalf("NewScheduleAnchored(%s, %s) first expect >= %s, got %s", tt.p, tt.anchor, expected, first)
+			}
+			if expected := now.Add(tt.want + inaccuracy); first.After(expected) {
+				t.Fatalf("NewScheduleAnchored(%s, %s) first expect <= %s, got %s", tt.p, tt.anchor, expected, first)
+			}
+			if got := s.Period(); got != tt.p {
+				t.Fatalf("NewScheduleAnchored(%s, %s) period expect %s, got %s", tt.p, tt.anchor, tt.p, got)
+			}
+		})
+	}
+}
+
+func TestScheduleAlignment(t *testing.T) {
+	type args struct {
+		p time.Duration
+		o time.Duration
+	}
+	tests := []struct {
+		name       string
+		args       args
+		wantOffset time.Duration
+	}{
+		{
+			name:       `sub-second period`,
+			args:       args{p: 333 * time.Millisecond, o: 0},
+			wantOffset: 0,
+		},
+		{
+			name:       `offset larger than period`,
+			args:       args{p: time.Second, o: 1500 * time.Millisecond},
+			wantOffset: 500 * time.Millisecond,
+		},
+		{
+			name:       `sub-second period with large offset`,
+			args:       args{p: 333 * time.Millisecond, o: 1500 * time.Millisecond},
+			wantOffset: 168 * time.Millisecond,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			start := time.Now()
+			s := NewSchedule(ctx, tt.args.p, tt.args.o)
+			if got := s.Offset(); got != tt.wantOffset {
+				t.Fatalf("Schedule(%s, %s) Offset() expect %s, got %s", tt.args.p, tt.args.o, tt.wantOffset, got)
+			}
+
+			first := <-s.C
+			second := <-s.C
+
+			if max := start.Add(tt.args.p + inaccuracy); first.After(max) {
+				t.Fatalf("Schedule(%s, %s) first expect <= %s, got %s", tt.args.p, tt.args.o, max, first)
+			}
+			gotPeriod := second.Sub(first)
+			if gotPeriod < tt.args.p-inaccuracy || gotPeriod > tt.args.p+inaccuracy {
+				t.Fatalf("Schedule(%s, %s) period expect %s, got %s", tt.args.p, tt.args.o, tt.args.p, gotPeriod)
+			}
+			gotOffset := time.Duration(first.UnixNano() % int64(tt.args.p))
+			if gotOffset < tt.wantOffset || gotOffset > tt.wantOffset+inaccuracy {
+				t.Fatalf("Schedule(%s, %s) offset expect %s, got %s", tt.args.p, tt.args.o, tt.wantOffset, gotOffset)
+			}
+		})
+	}
+}
+
+func TestScheduleNegativeOffset(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Schedule(1s, -1s) expect panic")
+		}
+	}()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	NewSchedule(ctx, time.Second, -time.Second)
+}
+
+func TestScheduleClose(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := NewSchedule(ctx, 100*time.Millisecond, 0)
+
+	done := make(chan int)
+	go func() {
+		n := 0
+		for range s.C {
+			n++
+			if n == 2 {
+				cancel()
+			}
+		}
+		done <- n
+	}()
+
+	select {
+	case n := <-done:
+		if n < 2 {
+			t.Fatalf("Schedule received %d ticks before close, expect >= 2", n)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Schedule channel was not closed after cancellation")
+	}
+	if _, ok := <-s.C; ok {
+		t.Fatal("Schedule channel expect closed")
+	}
+}
+
+func TestNextDaily(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		t    time.Time
+		at   time.Duration
+		want time.Time
+		// gap is set when at does not exist on the wall clock that day
+		gap      bool
+		wantNext time.Time
+	}{
+		{
+			name: `before`,
+			t:    time.Date(2021, 1, 10, 9, 0, 0, 0, ny),
+			at:   12 * time.Hour,
+			want: time.Date(2021, 1, 10, 12, 0, 0, 0, ny),
+		},
+		{
+			name: `exactly at`,
+			t:    time.Date(2021, 1, 10, 12, 0, 0, 0, ny),
+			at:   12 * time.Hour,
+			want: time.Date(2021, 1, 11, 12, 0, 0, 0, ny),
+		},
+		{
+			name: `other location`,
+			t:    time.Date(2021, 1, 10, 18, 0, 0, 0, time.UTC),
+			at:   12 * time.Hour,
+			want: time.Date(2021, 1, 11, 12, 0, 0, 0, ny),
+		},
+		{
+			name: `noon on the spring forward day`,
+			t:    time.Date(2021, 3, 13, 12, 0, 0, 0, ny),
+			at:   12 * time.Hour,
+			want: time.Date(2021, 3, 14, 12, 0, 0, 0, ny),
+		},
+		{
+			name: `noon on the fall back day`,
+			t:    time.Date(2021, 11, 6, 13, 0, 0, 0, ny),
+			at:   12*time.Hour + 30*time.Minute,
+			want: time.Date(2021, 11, 7, 12, 30, 0, 0, ny),
+		},
+		{
+			name: `skipped by spring forward`,
+			t:    time.Date(2021, 3, 13, 12, 0, 0, 0, ny),
+			at:   2*time.Hour + 30*time.Minute,
+			want: time.Date(2021, 3, 14, 7, 0, 0, 0, time.UTC),
+			gap:  true,
+		},
+		{
+			name: `created inside the gap`,
+			t:    time.Date(2021, 3, 14, 6, 45, 0, 0, time.UTC),
+			at:   2*time.Hour + 30*time.Minute,
+			want: time.Date(2021, 3, 14, 7, 0, 0, 0, time.UTC),
+			gap:  true,
+		},
+		{
+			name: `after the end of the gap`,
+			t:    time.Date(2021, 3, 14, 7, 0, 0, 0, time.UTC),
+			at:   2*time.Hour + 30*time.Minute,
+			want: time.Date(2021, 3, 15, 2, 30, 0, 0, ny),
+		},
+		{
+			name:     `repeated by fall back`,
+			t:        time.Date(2021, 11, 6, 12, 0, 0, 0, ny),
+			at:       time.Hour + 30*time.Minute,
+			want:     time.Date(2021, 11, 7, 5, 30, 0, 0, time.UTC),
+			wantNext: time.Date(2021, 11, 8, 6, 30, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := nextDaily(tt.t, tt.at, ny)
+			if !got.Equal(tt.want) {
+				t.Fatalf("nextDaily(%s, %s) expect %s, got %s", tt.t, tt.at, tt.want, got)
+			}
+			if h, m, _ := got.In(ny).Clock(); !tt.gap && time.Duration(h)*time.Hour+time.Duration(m)*time.Minute != tt.at {
+				t.Fatalf("nextDaily(%s, %s) expect wall clock %s, got %s", tt.t, tt.at, tt.at, got.In(ny))
+			}
+			if tt.wantNext.IsZero() {
+				return
+			}
+			if next := nextDaily(got, tt.at, ny); !next.Equal(tt.wantNext) {
+				t.Fatalf("nextDaily(%s, %s) expect %s, got %s", got, tt.at, tt.wantNext, next)
+			}
+		})
+	}
+}
+
+func TestDaily(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	loc := time.FixedZone("UTC+5:30", 5*60*60+30*60)
+	want := time.Now().Add(500 * time.Millisecond).In(loc)
+	y, m, d := want.Date()
+	at := want.Sub(time.Date(y, m, d, 0, 0, 0, 0, loc))
+	s := NewDaily(ctx, at, loc)
+
+	first := <-s.C
+	if first.Before(want) || first.After(want.Add(inaccuracy)) {
+		t.Fatalf("NewDaily(%s, %s) first expect %s, got %s", at, loc, want, first)
+	}
+}
+
+func TestScheduleWithPolicy(t *testing.T) {
+	tests := []struct {
+		name      string
+		mp        MissedPolicy
+		wantDelay time.Duration
+		wantSlot  time.Duration
+	}{
+		{
+			name:      `skip missed`,
+			mp:        SkipMissed,
+			wantDelay: time.Second / 2,
+			wantSlot:  time.Second,
+		},
+		{
+			name:      `run missed`,
+			mp:        RunMissed,
+			wantDelay: 0,
+			wantSlot:  0,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			// Start half way between two aligned ticks
+			now := time.Now()
+			<-time.After(now.Truncate(time.Second).Add(1500 * time.Millisecond).Sub(now))
+			start := time.Now()
+			s := NewScheduleWithPolicy(ctx, time.Second, 0, tt.mp)
+
+			var got time.Time
+			select {
+			case got = <-s.C:
+			case <-time.After(2 * time.Second):
+				t.Fatal("Schedule did not tick")
+			}
+			gotDelay := time.Since(start)
+			if gotDelay < tt.wantDelay-inaccuracy || gotDelay > tt.wantDelay+inaccuracy {
+				t.Fatalf("Schedule(1s, 0, %d) first tick expect after %s, got %s", tt.mp, tt.wantDelay, gotDelay)
+			}
+			want := start.Truncate(time.Second).Add(tt.wantSlot)
+			if tt.mp == RunMissed && !got.Equal(want) {
+				t.Fatalf("Schedule(1s, 0, %d) first value expect %s, got %s", tt.mp, want, got)
+			}
+			if tt.mp == SkipMissed && (got.Before(want) || got.After(want.Add(inaccuracy))) {
+				t.Fatalf("Schedule(1s, 0, %d) first value expect %s, got %s", tt.mp, want, got)
+			}
+		})
+	}
+}
+
+func TestScheduleOnDrift(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	const delay = 50 * time.Millisecond
+	var delayed int64
+	clock := func() time.Time {
+		return time.Now().Add(time.Duration(atomic.LoadInt64(&delayed)))
+	}
+
+	type drift struct{ expected, actual time.Time }
+	drifts := make(chan drift, 1)
+	ch := make(chan time.Time)
+	s := &Schedule{C: ch, p: time.Second, h: &hooks{}}
+	s.OnDrift(func(expected, actual time.Time) {
+		drifts <- drift{expected, actual}
+	})
+	firstT := time.Now().Add(100 * time.Millisecond)
+	s.start(ctx, ch, time.Now(), firstT, false, every(time.Second), clock)
+	// Delay the fire after the timer is armed
+	atomic.StoreInt64(&delayed, int64(delay))
+
+	<-s.C
+	got := <-drifts
+	if !got.expected.Equal(firstT) {
+		t.Fatalf("OnDrift() expected expect %s, got %s", firstT, got.expected)
+	}
+	if d := got.actual.Sub(got.expected); d < delay || d > delay+inaccuracy {
+		t.Fatalf("OnDrift() drift expect %s, got %s", delay, d)
+	}
+}
+
+func TestScheduleStopLeak(t *testing.T) {
+	baseline := runtime.NumGoroutine()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var schedules []*Schedule
+	for i := 0; i < 100; i++ {
+		schedules = append(schedules, NewSchedule(ctx, time.Millisecond, 0))
+	}
+	// Let every schedule block on a send nobody receives
+	<-time.After(20 * time.Millisecond)
+	for _, s := range schedules[:50] {
+		s.Stop()
+	}
+	// The rest is released by the context
+	cancel()
+
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > baseline {
+		if time.Now().After(deadline) {
+			t.Fatalf("Stop() goroutines expect <= %d, got %d", baseline, runtime.NumGoroutine())
+		}
+		<-time.After(10 * time.Millisecond)
+	}
+	for _, s := range schedules {
+		if _, ok := <-s.C; ok {
+			t.Fatal("Stop() channel expect closed")
+		}
+	}
+}
+
+func TestScheduleChecked(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	abidjan, err := time.LoadLocation("Africa/Abidjan")
+	if err != nil {
+		t.Fatal(err)
+	}
+	london, err := time.LoadLocation("Europe/London")
+	if err != nil {
+		t.Fatal(err)
+	}
+	kolkata, err := time.LoadLocation("Asia/Kolkata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	type args struct {
+		p   time.Duration
+		o   time.Duration
+		loc *time.Location
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr error
+	}{
+		{
+			name:    `daily in UTC`,
+			args:    args{p: 24 * time.Hour, o: 0, loc: time.UTC},
+			wantErr: nil,
+		},
+		{
+			name:    `daily at UTC midnight in a non-UTC zone`,
+			args:    args{p: 24 * time.Hour, o: 0, loc: time.FixedZone("UTC+5:30", 5*60*60+30*60)},
+			wantErr: ErrMisaligned,
+		},
+		{
+			name:    `daily at noon in a zone without offset`,
+			args:    args{p: 24 * time.Hour, o: 12 * time.Hour, loc: abidjan},
+			wantErr: nil,
+		},
+		{
+			name:    `daily at UTC time of day in a zone behind UTC`,
+			args:    args{p: 24 * time.Hour, o: 5 * time.Hour, loc: ny},
+			wantErr: ErrMisaligned,
+		},
+		{
+			name:    `daily across daylight saving time`,
+			args:    args{p: 24 * time.Hour, o: 0, loc: london},
+			wantErr: ErrMisaligned,
+		},
+		{
+			name:    `not a whole number of days`,
+			args:    args{p: 36 * time.Hour, o: 0, loc: time.UTC},
+			wantErr: ErrMisaligned,
+		},
+		{
+			name:    `daily at local midnight in a fixed offset zone`,
+			args:    args{p: 24 * time.Hour, o: 18*time.Hour + 30*time.Minute, loc: kolkata},
+			wantErr: nil,
+		},
+		{
+			name:    `weekly on epoch Thursdays`,
+			args:    args{p: 7 * 24 * time.Hour, o: 0, loc: time.UTC},
+			wantErr: ErrMisaligned,
+		},
+		{
+			name:    `weekly on Monday midnight`,
+			args:    args{p: 7 * 24 * time.Hour, o: 4 * 24 * time.Hour, loc: time.UTC},
+			wantErr: nil,
+		},
+		{
+			name:    `weekly on Monday noon`,
+			args:    args{p: 7 * 24 * time.Hour, o: 4*24*time.Hour + 12*time.Hour, loc: time.UTC},
+			wantErr: nil,
+		},
+		{
+			name:    `weekly on local Monday midnight in a fixed offset zone`,
+			args:    args{p: 7 * 24 * time.Hour, o: 3*24*time.Hour + 18*time.Hour + 30*time.Minute, loc: kolkata},
+			wantErr: nil,
+		},
+		{
+			name:    `shorter than a day`,
+			args:    args{p: time.Hour, o: 0, loc: ny},
+			wantErr: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			s, err := NewScheduleChecked(ctx, tt.args.p, tt.args.o, tt.args.loc)
+			if err != tt.wantErr {
+				t.Fatalf("NewScheduleChecked(%s, %s, %s) error = %v, wantErr %v", tt.args.p, tt.args.o, tt.args.loc, err, tt.wantErr)
+			}
+			if (s == nil) != (tt.wantErr != nil) {
+				t.Fatalf("NewScheduleChecked(%s, %s, %s) schedule = %v, wantErr %v", tt.args.p, tt.args.o, tt.args.loc, s, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTicker(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Start away from an aligned tick
+	now := time.Now()
+	<-time.After(now.Truncate(time.Second).Add(1300 * time.Millisecond).Sub(now))
+	start := time.Now()
+	s := NewTicker(ctx, time.Second)
+
+	first := <-s.C
+	second := <-s.C
+	if gotDelay := first.Sub(start); gotDelay < time.Second-inaccuracy || gotDelay > time.Second+inaccuracy {
+		t.Fatalf("Ticker(1s) first expect after 1s, got %s", gotDelay)
+	}
+	if gotPeriod := second.Sub(first); gotPeriod < time.Second-inaccuracy || gotPeriod > time.Second+inaccuracy {
+		t.Fatalf("Ticker(1s) period expect 1s, got %s", gotPeriod)
+	}
+}
+
+func TestScheduleCheckedPanic(t *testing.T) {
+	type args struct {
+		p   time.Duration
+		o   time.Duration
+		loc *time.Location
+	}
+	tests := []struct {
+		name string
+		args args
+	}{
+		{
+			name: `nil location`,
+			args: args{p: 24 * time.Hour, o: 0, loc: nil},
+		},
+		{
+			name: `non-positive period`,
+			args: args{p: 0, o: 0, loc: time.UTC},
+		},
+		{
+			name: `negative offset with a misaligned period`,
+			args: args{p: 36 * time.Hour, o: -time.Second, loc: time.UTC},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("NewScheduleChecked(%s, %s, %v) expect panic", tt.args.p, tt.args.o, tt.args.loc)
+				}
+			}()
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			NewScheduleChecked(ctx, tt.args.p, tt.args.o, tt.args.loc)
+		})
+	}
+}
+
+func TestScheduleOnDriftRunMissed(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	drifts := make(chan time.Duration, 2)
+	ch := make(chan time.Time)
+	s := &Schedule{C: ch, p: 100 * time.Millisecond, h: &hooks{}}
+	s.OnDrift(func(expected, actual time.Time) {
+		drifts <- actual.Sub(expected)
+	})
+	// The missed slot half a period ago
+	firstT := time.Now().Add(-50 * time.Millisecond)
+	s.start(ctx, ch, time.Now(), firstT, true, every(100*time.Millisecond), time.Now)
+
+	if got := <-s.C; !got.Equal(firstT) {
+		t.Fatalf("start() missed tick expect %s, got %s", firstT, got)
+	}
+	select {
+	case d := <-drifts:
+		t.Fatalf("OnDrift() expect no report for the missed tick, got %s", d)
+	default:
+	}
+
+	<-s.C
+	if d := <-drifts; d < 0 || d > inaccuracy {
+		t.Fatalf("OnDrift() drift expect <= %s, got %s", inaccuracy, d)
+	}
+}
+
+func TestScheduleAnchoredFarPast(t *testing.T) {
+	tests := []struct {
+		name   string
+		anchor time.Time
+		want   time.Duration
+	}{
+		{
+			name:   `zero anchor`,
+			anchor: time.Time{},
+			want:   0,
+		},
+		{
+			name:   `far past anchor`,
+			anchor: time.Date(1000, 1, 1, 0, 0, 0, 250*int(time.Millisecond), time.UTC),
+			want:   250 * time.Millisecond,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			start := time.Now()
+			s := NewScheduleAnchored(ctx, time.Second, tt.anchor)
+
+			first := <-s.C
+			second := <-s.C
+			if first.Before(start) || first.After(start.Add(time.Second+inaccuracy)) {
+				t.Fatalf("NewScheduleAnchored(1s, %s) first expect within a period of %s, got %s", tt.anchor, start, first)
+			}
+			if gotOffset := time.Duration(first.UnixNano() % int64(time.Second)); gotOffset < tt.want || gotOffset > tt.want+inaccuracy {
+				t.Fatalf("NewScheduleAnchored(1s, %s) offset expect %s, got %s", tt.anchor, tt.want, gotOffset)
+			}
+			if gotPeriod := second.Sub(first); gotPeriod < time.Second-inaccuracy || gotPeriod > time.Second+inaccuracy {
+				t.Fatalf("NewScheduleAnchored(1s, %s) period expect 1s, got %s", tt.anchor, gotPeriod)
+			}
+			if got := s.Offset(); got != tt.want {
+				t.Fatalf("NewScheduleAnchored(1s, %s) Offset() expect %s, got %s", tt.anchor, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestStartPastFirstTick(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("start() with a first tick two periods ago expect panic")
+		}
+	}()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch := make(chan time.Time)
+	s := &Schedule{C: ch, p: time.Second, h: &hooks{}}
+	now := time.Now()
+	s.start(ctx, ch, now, now.Add(-2*time.Second), false, every(time.Second), time.Now)
+}
+
+func TestScheduleTinyPeriod(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s := NewSchedule(ctx, time.Nanosecond, 0)
+	<-s.C
+	// Leave a lot of ticks behind
+	<-time.After(500 * time.Millisecond)
+	<-s.C
+	start := time.Now()
+	<-s.C
+	if got := time.Since(start); got > 100*time.Millisecond {
+		t.Fatalf("Schedule(1ns, 0) tick after a pause expect <= 100ms, got %s", got)
+	}
+}
+
+func TestEvery(t *testing.T) {
+	prev := time.Unix(0, 0)
+	tests := []struct {
+		name       string
+		now        time.Time
+		want       time.Time
+		wantMissed int
+	}{
+		{
+			name:       `next is ahead`,
+			now:        prev.Add(time.Second / 2),
+			want:       prev.Add(time.Second),
+			wantMissed: 0,
+		},
+		{
+			name:       `next is now`,
+			now:        prev.Add(time.Second),
+			want:       prev.Add(2 * time.Second),
+			wantMissed: 1,
+		},
+		{
+			name:       `far behind`,
+			now:        prev.Add(1000*time.Second + 1),
+			want:       prev.Add(1001 * time.Second),
+			wantMissed: 1000,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotMissed := every(time.Second)(prev, tt.now)
+			if !got.Equal(tt.want) {
+				t.Fatalf("every(1s)(%s, %s) expect %s, got %s", prev, tt.now, tt.want, got)
+			}
+			if gotMissed != tt.wantMissed {
+				t.Fatalf("every(1s)(%s, %s) missed expect %d, got %d", prev, tt.now, tt.wantMissed, gotMissed)
+			}
+		})
+	}
+}
+
+func TestScheduleOnSkip(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	skips := make(chan int, 1)
+	s := NewSchedule(ctx, 100*time.Millisecond, 0)
+	s.OnSkip(func(missed int, at time.Time) {
+		skips <- missed
+	})
+
+	<-s.C
+	// The next tick waits for the receiver while three more pass
+	<-time.After(450 * time.Millisecond)
+	<-s.C
+	if got := <-skips; got != 3 {
+		t.Fatalf("OnSkip() missed expect 3, got %d", got)
+	}
+}